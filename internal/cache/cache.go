@@ -0,0 +1,233 @@
+// Package cache provides a cache for anime listings and per-anime
+// metadata (the metadata is backed by disk, the listings are kept in
+// memory only), with TTL-based staleness rules similar to the
+// ones go-anidb uses for its title/episode cache: shows that are still
+// airing go stale quickly, finished shows are considered fresh for a
+// long time.
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// IncompleteCacheDuration is how long an AnimeInfo for a still-airing
+	// show is considered fresh before it needs to be refetched.
+	IncompleteCacheDuration = 6 * time.Hour
+	// CacheDuration is a staleness window between
+	// IncompleteCacheDuration and FinishedCacheDuration. IsStale does
+	// not apply it: an entry is stored only as finished or not finished.
+	CacheDuration = 24 * time.Hour
+	// FinishedCacheDuration is how long a completed show's AnimeInfo is
+	// trusted before it's refetched, since it's not expected to change.
+	FinishedCacheDuration = 7 * 24 * time.Hour
+	// ListingsCacheDuration is how long the `/animes` catalogue is
+	// considered fresh before a caller needs to re-scrape it.
+	ListingsCacheDuration = 1 * time.Hour
+)
+
+type animeEntry struct {
+	Info      AnimeInfo `json:"info"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Finished  bool      `json:"finished"`
+}
+
+// Store is an in-memory cache of anime listings and metadata. Per-anime
+// metadata is mirrored to disk so a restart doesn't lose what's already
+// been scraped; the listings are not written to disk and are empty
+// after a restart until SetListings runs again.
+type Store struct {
+	dir string
+
+	mu                sync.RWMutex
+	listings          []AnimeEntry
+	listingsFetchedAt time.Time
+	animes            map[string]*animeEntry
+
+	onListingsUpdated func([]AnimeEntry)
+}
+
+// NewStore creates a Store rooted at dir (e.g. "./cache") and loads any
+// previously cached AnimeInfo entries from dir/meta/*.json.
+func NewStore(dir string) (*Store, error) {
+	s := &Store{
+		dir:    dir,
+		animes: make(map[string]*animeEntry),
+	}
+
+	if err := os.MkdirAll(s.metaDir(), 0o755); err != nil {
+		return nil, err
+	}
+
+	if err := s.loadFromDisk(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Store) metaDir() string {
+	return filepath.Join(s.dir, "meta")
+}
+
+func (s *Store) metaPath(id string) string {
+	return filepath.Join(s.metaDir(), id+".json")
+}
+
+func (s *Store) loadFromDisk() error {
+	files, err := os.ReadDir(s.metaDir())
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+
+		raw, err := os.ReadFile(filepath.Join(s.metaDir(), f.Name()))
+		if err != nil {
+			continue
+		}
+
+		var e animeEntry
+		if err := json.Unmarshal(raw, &e); err != nil {
+			continue
+		}
+
+		id := strimExt(f.Name())
+		s.animes[id] = &e
+	}
+
+	return nil
+}
+
+func strimExt(name string) string {
+	return name[:len(name)-len(filepath.Ext(name))]
+}
+
+// SetListings replaces the cached `/animes` catalogue and notifies the
+// OnListingsUpdated callback, if one is set.
+func (s *Store) SetListings(entries []AnimeEntry) {
+	s.mu.Lock()
+	s.listings = entries
+	s.listingsFetchedAt = time.Now()
+	cb := s.onListingsUpdated
+	s.mu.Unlock()
+
+	if cb != nil {
+		cb(entries)
+	}
+}
+
+// OnListingsUpdated registers fn to be called with the new catalogue
+// every time SetListings runs, so dependents (e.g. a search index) can
+// stay in sync without polling. Only one callback is kept; a later call
+// replaces the previous one.
+func (s *Store) OnListingsUpdated(fn func([]AnimeEntry)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onListingsUpdated = fn
+}
+
+// Listings returns the cached `/animes` catalogue, or nil if it hasn't
+// been populated yet.
+func (s *Store) Listings() []AnimeEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.listings
+}
+
+// IsListingsStale reports whether the cached catalogue is missing or
+// past ListingsCacheDuration.
+func (s *Store) IsListingsStale() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.listings == nil {
+		return true
+	}
+
+	return time.Since(s.listingsFetchedAt) > ListingsCacheDuration
+}
+
+// PutAnime stores info for id, marking it finished (so it gets the long
+// FinishedCacheDuration TTL) or still airing. The entry is persisted to
+// disk immediately.
+func (s *Store) PutAnime(id string, info AnimeInfo, finished bool) error {
+	e := &animeEntry{
+		Info:      info,
+		FetchedAt: time.Now(),
+		Finished:  finished,
+	}
+
+	s.mu.Lock()
+	s.animes[id] = e
+	s.mu.Unlock()
+
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.metaPath(id), raw, 0o644)
+}
+
+// GetAnime returns the cached AnimeInfo for id, if any. The bool return
+// says nothing about staleness; check IsStale separately.
+func (s *Store) GetAnime(id string) (*AnimeInfo, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.animes[id]
+	if !ok {
+		return nil, false
+	}
+
+	info := e.Info
+	return &info, true
+}
+
+// IsStale reports whether the cached entry for id is missing or past
+// its TTL. Finished shows get FinishedCacheDuration; every other entry,
+// i.e. anything stored via PutAnime(finished=false), gets the shorter
+// IncompleteCacheDuration. CacheDuration is not used.
+func (s *Store) IsStale(id string) bool {
+	s.mu.RLock()
+	e, ok := s.animes[id]
+	s.mu.RUnlock()
+
+	if !ok {
+		return true
+	}
+
+	ttl := CacheDuration
+	if e.Finished {
+		ttl = FinishedCacheDuration
+	} else {
+		ttl = IncompleteCacheDuration
+	}
+
+	return time.Since(e.FetchedAt) > ttl
+}
+
+// KnownIDs returns the ids of every anime currently held in the cache,
+// stale or not. Used by the background refresher to decide what to
+// revisit.
+func (s *Store) KnownIDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]string, 0, len(s.animes))
+	for id := range s.animes {
+		ids = append(ids, id)
+	}
+	return ids
+}
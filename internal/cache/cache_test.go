@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsStaleUsesFinishedTTL(t *testing.T) {
+	s := &Store{animes: make(map[string]*animeEntry)}
+
+	if !s.IsStale("unknown") {
+		t.Error("IsStale should report true for an id that was never stored")
+	}
+
+	s.animes["finished"] = &animeEntry{FetchedAt: time.Now().Add(-2 * time.Hour), Finished: true}
+	if s.IsStale("finished") {
+		t.Error("a finished show fetched 2h ago should still be fresh under FinishedCacheDuration")
+	}
+
+	s.animes["airing"] = &animeEntry{FetchedAt: time.Now().Add(-7 * time.Hour), Finished: false}
+	if !s.IsStale("airing") {
+		t.Error("a still-airing show fetched 7h ago should be stale under IncompleteCacheDuration")
+	}
+}
+
+func TestIsListingsStale(t *testing.T) {
+	s := &Store{}
+
+	if !s.IsListingsStale() {
+		t.Error("IsListingsStale should report true before any listings have been set")
+	}
+
+	s.SetListings([]AnimeEntry{{ID: "one"}})
+	if s.IsListingsStale() {
+		t.Error("listings just set should be fresh")
+	}
+
+	s.listingsFetchedAt = time.Now().Add(-2 * ListingsCacheDuration)
+	if !s.IsListingsStale() {
+		t.Error("listings older than ListingsCacheDuration should be stale")
+	}
+}
+
+func TestSetListingsNotifiesCallback(t *testing.T) {
+	s := &Store{}
+
+	var got []AnimeEntry
+	s.OnListingsUpdated(func(entries []AnimeEntry) { got = entries })
+
+	s.SetListings([]AnimeEntry{{ID: "one"}, {ID: "two"}})
+
+	if len(got) != 2 {
+		t.Fatalf("callback received %d entries, want 2", len(got))
+	}
+}
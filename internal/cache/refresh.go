@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"log"
+	"time"
+)
+
+// FetchListingsFunc fetches a fresh `/animes` catalogue.
+type FetchListingsFunc func() ([]AnimeEntry, error)
+
+// FetchAnimeFunc fetches a fresh AnimeInfo for id, reporting whether the
+// show is finished so the caller can apply the right TTL.
+type FetchAnimeFunc func(id string) (info AnimeInfo, finished bool, err error)
+
+// StartRefresher populates the listings cache immediately and then, on
+// the given interval, refreshes the listings plus any known anime whose
+// cache entry has gone stale. It runs until the process exits.
+func (s *Store) StartRefresher(interval time.Duration, fetchListings FetchListingsFunc, fetchAnime FetchAnimeFunc) {
+	refresh := func() {
+		entries, err := fetchListings()
+		if err != nil {
+			log.Printf("cache: refreshing listings failed: %s\n", err)
+		} else {
+			s.SetListings(entries)
+		}
+
+		for _, id := range s.KnownIDs() {
+			if !s.IsStale(id) {
+				continue
+			}
+
+			info, finished, err := fetchAnime(id)
+			if err != nil {
+				log.Printf("cache: refreshing anime %q failed: %s\n", id, err)
+				continue
+			}
+
+			if err := s.PutAnime(id, info, finished); err != nil {
+				log.Printf("cache: persisting anime %q failed: %s\n", id, err)
+			}
+		}
+	}
+
+	go func() {
+		refresh()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			refresh()
+		}
+	}()
+}
@@ -0,0 +1,30 @@
+package cache
+
+// AnimeEntry is a single row of the `/animes` catalogue listing.
+type AnimeEntry struct {
+	ID                string   `json:"id"`
+	Title             string   `json:"title"`
+	AlternativeTitles []string `json:"alternative_titles"`
+	LinkPath          string   `json:"link_path"`
+}
+
+// AnimeInfo is the full detail page for a single anime, including every
+// episode across every season.
+type AnimeInfo struct {
+	Thumbnail string         `json:"thumbnail"`
+	Title     string         `json:"title"`
+	Summary   string         `json:"summary"`
+	Episodes  []EpisodeEntry `json:"episodes"`
+}
+
+type EpisodeEntry struct {
+	// ID is a stable, globally unique identifier of the form
+	// "<animeId>-s<season>-e<episode>", unlike LinkPath which depends on
+	// aniworld's own URL structure.
+	ID             string `json:"id"`
+	LinkPath       string `json:"link_path"`
+	Title          string `json:"title"`
+	SecondaryTitle string `json:"secondary_title"`
+	EpisodeNr      string `json:"episode"`
+	SeasonNr       string `json:"season"`
+}
@@ -0,0 +1,48 @@
+// Package downloader runs background download jobs: it resolves an
+// episode to a direct stream via a caller-supplied ResolveFunc, fetches
+// it to disk, and tracks progress for a persistent, on-disk job store.
+package downloader
+
+import "time"
+
+// Status is a job's place in its lifecycle.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Progress is a snapshot of how far a running download has gotten.
+type Progress struct {
+	BytesDownloaded int64   `json:"bytes_downloaded"`
+	TotalBytes      int64   `json:"total_bytes"`
+	SpeedBytesPerS  float64 `json:"speed_bytes_per_sec"`
+	ETASeconds      float64 `json:"eta_seconds"`
+}
+
+// Job is a single enqueued download and its current state.
+type Job struct {
+	ID        string    `json:"id"`
+	EpisodeID string    `json:"episode_id"`
+	Quality   string    `json:"quality,omitempty"`
+	Language  string    `json:"language,omitempty"`
+	Status    Status    `json:"status"`
+	Progress  Progress  `json:"progress"`
+	FilePath  string    `json:"file_path,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (j *Job) terminal() bool {
+	switch j.Status {
+	case StatusCompleted, StatusFailed, StatusCancelled:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,282 @@
+package downloader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrJobNotFound is returned by Get/Cancel when no job with the given
+// id is known.
+var ErrJobNotFound = errors.New("downloader: job not found")
+
+// ResolvedDownload is what a ResolveFunc hands back: where to fetch the
+// stream from and where to write it.
+type ResolvedDownload struct {
+	StreamURL string
+	Headers   map[string]string
+	DestPath  string
+}
+
+// ResolveFunc turns a queued job's episode/quality/language preference
+// into a concrete, fetchable stream. It's supplied by the caller so
+// this package stays ignorant of aniworld-specific scraping and
+// extractor details.
+type ResolveFunc func(ctx context.Context, episodeID, quality, language string) (ResolvedDownload, error)
+
+// Manager runs a bounded pool of download workers against a queue of
+// jobs, persisting job state to disk so it survives a restart.
+type Manager struct {
+	dir         string
+	concurrency int
+	resolve     ResolveFunc
+
+	// Client is used to fetch the resolved stream. It defaults to
+	// http.DefaultClient (no timeout) if left unset; callers should set
+	// it to a timeout-bearing client so a stalled upstream can't hang a
+	// worker forever.
+	Client *http.Client
+	// UserAgent, if set, is sent on every stream fetch.
+	UserAgent string
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	cancels map[string]context.CancelFunc
+	subs    map[string][]chan Job
+
+	queue chan string
+}
+
+// NewManager creates a Manager rooted at dir (e.g. "./downloads"),
+// loading any job state left over from a previous run.
+func NewManager(dir string, concurrency int) (*Manager, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	m := &Manager{
+		dir:         dir,
+		concurrency: concurrency,
+		jobs:        make(map[string]*Job),
+		cancels:     make(map[string]context.CancelFunc),
+		subs:        make(map[string][]chan Job),
+		queue:       make(chan string, 256),
+	}
+
+	if err := os.MkdirAll(m.jobsDir(), 0o755); err != nil {
+		return nil, err
+	}
+
+	if err := m.loadFromDisk(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *Manager) jobsDir() string {
+	return filepath.Join(m.dir, "jobs")
+}
+
+func (m *Manager) jobPath(id string) string {
+	return filepath.Join(m.jobsDir(), id+".json")
+}
+
+func (m *Manager) loadFromDisk() error {
+	files, err := os.ReadDir(m.jobsDir())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+
+		raw, err := os.ReadFile(filepath.Join(m.jobsDir(), f.Name()))
+		if err != nil {
+			continue
+		}
+
+		var job Job
+		if err := json.Unmarshal(raw, &job); err != nil {
+			continue
+		}
+
+		m.jobs[job.ID] = &job
+	}
+
+	return nil
+}
+
+// Start launches the worker pool and requeues any job that was left
+// queued or running when the process last exited.
+func (m *Manager) Start(resolve ResolveFunc) {
+	m.resolve = resolve
+
+	for i := 0; i < m.concurrency; i++ {
+		go m.worker()
+	}
+
+	m.mu.Lock()
+	var pending []string
+	for _, job := range m.jobs {
+		if job.Status == StatusQueued || job.Status == StatusRunning {
+			job.Status = StatusQueued
+			pending = append(pending, job.ID)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, id := range pending {
+		m.queue <- id
+	}
+}
+
+func newJobID() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// Enqueue creates a new queued job for episodeID and schedules it onto
+// the worker pool.
+func (m *Manager) Enqueue(episodeID, quality, language string) (*Job, error) {
+	now := time.Now()
+	job := &Job{
+		ID:        newJobID(),
+		EpisodeID: episodeID,
+		Quality:   quality,
+		Language:  language,
+		Status:    StatusQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	m.mu.Lock()
+	m.jobs[job.ID] = job
+	m.mu.Unlock()
+
+	if err := m.persist(job); err != nil {
+		return nil, err
+	}
+
+	m.queue <- job.ID
+
+	return job, nil
+}
+
+// List returns every known job, queued through terminal.
+func (m *Manager) List() []*Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		clone := *job
+		jobs = append(jobs, &clone)
+	}
+	return jobs
+}
+
+// Get returns a copy of the job for id.
+func (m *Manager) Get(id string) (*Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, false
+	}
+	clone := *job
+	return &clone, true
+}
+
+// Cancel stops a running job, or marks a queued one cancelled before it
+// ever starts.
+func (m *Manager) Cancel(id string) error {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	cancel, running := m.cancels[id]
+	m.mu.Unlock()
+
+	if !ok {
+		return ErrJobNotFound
+	}
+
+	if running {
+		cancel()
+		return nil
+	}
+
+	if job.terminal() {
+		return nil
+	}
+
+	m.setStatus(job, StatusCancelled, "")
+	return nil
+}
+
+// Subscribe returns a channel of progress updates for id, plus an
+// unsubscribe func the caller must call when done listening.
+func (m *Manager) Subscribe(id string) (<-chan Job, func()) {
+	ch := make(chan Job, 8)
+
+	m.mu.Lock()
+	m.subs[id] = append(m.subs[id], ch)
+	m.mu.Unlock()
+
+	unsubscribe := func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		subs := m.subs[id]
+		for i, c := range subs {
+			if c == ch {
+				m.subs[id] = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+func (m *Manager) broadcast(job Job) {
+	m.mu.Lock()
+	subs := append([]chan Job(nil), m.subs[job.ID]...)
+	m.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- job:
+		default:
+		}
+	}
+}
+
+func (m *Manager) persist(job *Job) error {
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.jobPath(job.ID), raw, 0o644)
+}
+
+func (m *Manager) setStatus(job *Job, status Status, errMsg string) {
+	m.mu.Lock()
+	job.Status = status
+	job.Error = errMsg
+	job.UpdatedAt = time.Now()
+	m.mu.Unlock()
+
+	_ = m.persist(job)
+	m.broadcast(*job)
+}
@@ -0,0 +1,170 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func (m *Manager) worker() {
+	for id := range m.queue {
+		m.runJob(id)
+	}
+}
+
+func (m *Manager) runJob(id string) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	m.mu.Unlock()
+
+	if !ok || job.terminal() {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mu.Lock()
+	m.cancels[id] = cancel
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.cancels, id)
+		m.mu.Unlock()
+		cancel()
+	}()
+
+	m.setStatus(job, StatusRunning, "")
+
+	resolved, err := m.resolve(ctx, job.EpisodeID, job.Quality, job.Language)
+	if err != nil {
+		m.setStatus(job, StatusFailed, err.Error())
+		return
+	}
+
+	if err := m.download(ctx, job, resolved); err != nil {
+		if ctx.Err() != nil {
+			m.setStatus(job, StatusCancelled, "")
+			return
+		}
+		m.setStatus(job, StatusFailed, err.Error())
+		return
+	}
+
+	m.mu.Lock()
+	job.FilePath = resolved.DestPath
+	m.mu.Unlock()
+
+	m.setStatus(job, StatusCompleted, "")
+}
+
+// progressInterval is the minimum gap between progress broadcasts, so a
+// fast connection doesn't flood subscribers.
+const progressInterval = 500 * time.Millisecond
+
+func (m *Manager) download(ctx context.Context, job *Job, resolved ResolvedDownload) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resolved.StreamURL, nil)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range resolved.Headers {
+		req.Header.Set(k, v)
+	}
+	if m.UserAgent != "" {
+		req.Header.Set("User-Agent", m.UserAgent)
+	}
+
+	client := m.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("downloader: unexpected status fetching stream: %s", res.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(resolved.DestPath), 0o755); err != nil {
+		return err
+	}
+
+	out, err := os.Create(resolved.DestPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	total := res.ContentLength
+
+	var written int64
+	start := time.Now()
+	lastReport := start
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := res.Body.Read(buf)
+		if n > 0 {
+			if _, err := out.Write(buf[:n]); err != nil {
+				return err
+			}
+			written += int64(n)
+
+			if now := time.Now(); now.Sub(lastReport) >= progressInterval {
+				m.reportProgress(job, written, total, start)
+				lastReport = now
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+	}
+
+	m.reportProgress(job, written, total, start)
+
+	return nil
+}
+
+func (m *Manager) reportProgress(job *Job, written, total int64, start time.Time) {
+	elapsed := time.Since(start).Seconds()
+
+	var speed float64
+	if elapsed > 0 {
+		speed = float64(written) / elapsed
+	}
+
+	var eta float64
+	if speed > 0 && total > 0 {
+		eta = float64(total-written) / speed
+	}
+
+	m.mu.Lock()
+	job.Progress = Progress{
+		BytesDownloaded: written,
+		TotalBytes:      total,
+		SpeedBytesPerS:  speed,
+		ETASeconds:      eta,
+	}
+	job.UpdatedAt = time.Now()
+	m.mu.Unlock()
+
+	_ = m.persist(job)
+	m.broadcast(*job)
+}
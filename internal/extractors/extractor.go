@@ -0,0 +1,25 @@
+// Package extractors resolves aniworld hoster links into direct,
+// playable stream URLs. Each hoster gets its own StreamExtractor
+// implementation; unknown hosters fall back to HTTPFallback, which
+// queries an external resolver service over HTTP.
+package extractors
+
+import "context"
+
+// Stream is a single playable candidate resolved from a hoster link.
+type Stream struct {
+	URL      string            `json:"url"`
+	Quality  string            `json:"quality"`
+	Language string            `json:"language"`
+	MimeType string            `json:"mime_type"`
+	Hoster   string            `json:"hoster"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+// StreamExtractor knows how to resolve one hoster's embed page into a
+// direct stream.
+type StreamExtractor interface {
+	Name() string
+	Supports(hosterURL string) bool
+	Extract(ctx context.Context, hosterURL string) (Stream, error)
+}
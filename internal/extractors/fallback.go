@@ -0,0 +1,66 @@
+package extractors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// HTTPFallback delegates to an external resolver service for any
+// hoster without a dedicated extractor yet. It always reports
+// Supports() == true, so it must be registered last to act as a
+// catch-all.
+type HTTPFallback struct {
+	ResolverURL string
+	Client      *http.Client
+}
+
+// NewHTTPFallback returns a fallback extractor backed by resolverURL
+// (e.g. "http://localhost:3000").
+func NewHTTPFallback(resolverURL string) *HTTPFallback {
+	return &HTTPFallback{ResolverURL: resolverURL}
+}
+
+func (f *HTTPFallback) Name() string { return "http-fallback" }
+
+func (f *HTTPFallback) Supports(hosterURL string) bool { return true }
+
+func (f *HTTPFallback) Extract(ctx context.Context, hosterURL string) (Stream, error) {
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.ResolverURL+"/?url="+url.QueryEscape(hosterURL), nil)
+	if err != nil {
+		return Stream{}, err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return Stream{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Stream{}, fmt.Errorf("http-fallback: unexpected status: %s", res.Status)
+	}
+
+	var data struct {
+		URL      string `json:"url"`
+		Quality  string `json:"quality"`
+		MimeType string `json:"mime_type"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&data); err != nil {
+		return Stream{}, err
+	}
+
+	return Stream{
+		URL:      data.URL,
+		Quality:  data.Quality,
+		MimeType: data.MimeType,
+		Hoster:   f.Name(),
+	}, nil
+}
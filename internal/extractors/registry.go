@@ -0,0 +1,27 @@
+package extractors
+
+// registry holds every registered extractor in registration order.
+// Order matters: For returns the first match, so specific hosters
+// should be registered before any catch-all fallback.
+var registry []StreamExtractor
+
+// Register adds e to the set of extractors consulted by For.
+func Register(e StreamExtractor) {
+	registry = append(registry, e)
+}
+
+// For returns the first registered extractor that supports hosterURL,
+// or nil if none do.
+func For(hosterURL string) StreamExtractor {
+	for _, e := range registry {
+		if e.Supports(hosterURL) {
+			return e
+		}
+	}
+	return nil
+}
+
+// All returns every registered extractor.
+func All() []StreamExtractor {
+	return registry
+}
@@ -0,0 +1,66 @@
+package extractors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// vidozaSourcePattern pulls the direct MP4 URL out of Vidoza's embed
+// page, which serves it straight in a <source> tag.
+var vidozaSourcePattern = regexp.MustCompile(`<source src="([^"]+)" type='video/mp4'>`)
+
+// vidozaQuality is the single resolution Vidoza serves for aniworld
+// embeds; the embed page has no quality switcher to parse, so this is
+// reported as-is rather than left blank.
+const vidozaQuality = "720p"
+
+// Vidoza extracts streams from vidoza.net embeds.
+type Vidoza struct {
+	Client *http.Client
+}
+
+func (v Vidoza) Name() string { return "Vidoza" }
+
+func (v Vidoza) Supports(hosterURL string) bool {
+	return strings.Contains(hosterURL, "vidoza.net")
+}
+
+func (v Vidoza) Extract(ctx context.Context, hosterURL string) (Stream, error) {
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hosterURL, nil)
+	if err != nil {
+		return Stream{}, err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return Stream{}, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return Stream{}, err
+	}
+
+	m := vidozaSourcePattern.FindSubmatch(body)
+	if m == nil {
+		return Stream{}, fmt.Errorf("vidoza: no source found in embed page")
+	}
+
+	return Stream{
+		URL:      string(m[1]),
+		Quality:  vidozaQuality,
+		MimeType: "video/mp4",
+		Hoster:   v.Name(),
+		Headers:  map[string]string{"Referer": hosterURL},
+	}, nil
+}
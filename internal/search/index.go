@@ -0,0 +1,171 @@
+// Package search provides fuzzy lookup over the anime catalogue using
+// an inverted trigram index, so a query only has to score the handful
+// of entries that actually share substrings with it instead of every
+// entry in the catalogue.
+package search
+
+import (
+	"sort"
+
+	"github.com/Roritchi/aniworld-api/internal/cache"
+	"github.com/agnivade/levenshtein"
+)
+
+const (
+	// titleWeight and altTitleWeight bias matches on an entry's primary
+	// title above its alternative titles.
+	titleWeight    = 1.0
+	altTitleWeight = 0.6
+
+	// levenshteinTopK caps how many top trigram candidates get the
+	// (comparatively expensive) exact edit-distance tie-breaker.
+	levenshteinTopK = 50
+)
+
+// Result is a single scored catalogue match.
+type Result struct {
+	Entry cache.AnimeEntry `json:"entry"`
+	Score float64          `json:"score"`
+}
+
+type candidate struct {
+	entry       cache.AnimeEntry
+	exact       bool
+	jaccard     float64
+	levenshtein int
+}
+
+// Index is an inverted trigram index over a catalogue snapshot, built
+// once and queried many times.
+type Index struct {
+	entries  []cache.AnimeEntry
+	titleTri []trigramSet
+	altTri   [][]trigramSet
+	postings map[string][]int
+}
+
+// NewIndex builds an Index over entries. Rebuild it (cheaply) whenever
+// the catalogue changes; it's read-only once built.
+func NewIndex(entries []cache.AnimeEntry) *Index {
+	idx := &Index{
+		entries:  entries,
+		titleTri: make([]trigramSet, len(entries)),
+		altTri:   make([][]trigramSet, len(entries)),
+		postings: make(map[string][]int),
+	}
+
+	for i, e := range entries {
+		title := trigramsOf(e.Title)
+		idx.titleTri[i] = title
+		idx.post(i, title)
+
+		alts := make([]trigramSet, len(e.AlternativeTitles))
+		for j, alt := range e.AlternativeTitles {
+			t := trigramsOf(alt)
+			alts[j] = t
+			idx.post(i, t)
+		}
+		idx.altTri[i] = alts
+	}
+
+	return idx
+}
+
+func (idx *Index) post(entryIdx int, tri trigramSet) {
+	for t := range tri {
+		idx.postings[t] = append(idx.postings[t], entryIdx)
+	}
+}
+
+// Query returns catalogue entries matching phrase, highest score
+// first. limit <= 0 means no cap; results below minScore are dropped
+// unless they also have an exact word match, which always ranks above
+// every fuzzy-only result regardless of score.
+func (idx *Index) Query(phrase string, limit int, minScore float64) []Result {
+	queryTri := trigramsOf(phrase)
+
+	candidateIdx := make(map[int]struct{})
+	for t := range queryTri {
+		for _, i := range idx.postings[t] {
+			candidateIdx[i] = struct{}{}
+		}
+	}
+
+	// A query sharing no trigram with anything (very short or unusual
+	// input) still deserves a best-effort scan of the whole catalogue.
+	if len(candidateIdx) == 0 {
+		for i := range idx.entries {
+			candidateIdx[i] = struct{}{}
+		}
+	}
+
+	candidates := make([]candidate, 0, len(candidateIdx))
+	for i := range candidateIdx {
+		e := idx.entries[i]
+
+		best := jaccard(idx.titleTri[i], queryTri) * titleWeight
+		for _, alt := range idx.altTri[i] {
+			if score := jaccard(alt, queryTri) * altTitleWeight; score > best {
+				best = score
+			}
+		}
+
+		titles := append([]string{e.Title}, e.AlternativeTitles...)
+
+		candidates = append(candidates, candidate{
+			entry:       e,
+			exact:       hasExactWordMatch(phrase, titles),
+			jaccard:     best,
+			levenshtein: -1,
+		})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].exact != candidates[j].exact {
+			return candidates[i].exact
+		}
+		return candidates[i].jaccard > candidates[j].jaccard
+	})
+
+	topK := levenshteinTopK
+	if topK > len(candidates) {
+		topK = len(candidates)
+	}
+
+	for i := 0; i < topK; i++ {
+		titles := append([]string{candidates[i].entry.Title}, candidates[i].entry.AlternativeTitles...)
+
+		best := -1
+		for _, title := range titles {
+			if d := levenshtein.ComputeDistance(phrase, title); best == -1 || d < best {
+				best = d
+			}
+		}
+		candidates[i].levenshtein = best
+	}
+
+	sort.SliceStable(candidates[:topK], func(i, j int) bool {
+		if candidates[i].exact != candidates[j].exact {
+			return candidates[i].exact
+		}
+		if candidates[i].jaccard != candidates[j].jaccard {
+			return candidates[i].jaccard > candidates[j].jaccard
+		}
+		return candidates[i].levenshtein < candidates[j].levenshtein
+	})
+
+	var results []Result
+	for _, c := range candidates {
+		if !c.exact && c.jaccard < minScore {
+			continue
+		}
+
+		results = append(results, Result{Entry: c.entry, Score: c.jaccard})
+
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,49 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/Roritchi/aniworld-api/internal/cache"
+)
+
+func TestQueryExactMatchOutranksFuzzy(t *testing.T) {
+	idx := NewIndex([]cache.AnimeEntry{
+		{ID: "1", Title: "Naruto"},
+		{ID: "2", Title: "Narato Shippuden"},
+	})
+
+	results := idx.Query("naruto", 0, 0)
+	if len(results) == 0 || results[0].Entry.ID != "1" {
+		t.Fatalf("expected the exact word match to rank first, got %+v", results)
+	}
+}
+
+func TestQueryWeightsTitleOverAlternative(t *testing.T) {
+	idx := NewIndex([]cache.AnimeEntry{
+		{ID: "title-match", Title: "Bleach"},
+		{ID: "alt-match", Title: "Zzz", AlternativeTitles: []string{"Bleach"}},
+	})
+
+	results := idx.Query("bleach", 0, 0)
+	if len(results) < 2 {
+		t.Fatalf("expected both entries to match, got %+v", results)
+	}
+	if results[0].Entry.ID != "title-match" {
+		t.Errorf("expected the primary-title match to outrank the alt-title match, got %+v", results)
+	}
+}
+
+func TestQueryRespectsLimitAndMinScore(t *testing.T) {
+	idx := NewIndex([]cache.AnimeEntry{
+		{ID: "1", Title: "One Piece"},
+		{ID: "2", Title: "Completely Unrelated Title"},
+	})
+
+	if results := idx.Query("one piece", 1, 0); len(results) != 1 {
+		t.Errorf("limit=1 should cap results to 1, got %d", len(results))
+	}
+
+	if results := idx.Query("one piece", 0, 0.99); len(results) != 1 {
+		t.Errorf("a high min_score should drop everything but the near-exact match, got %+v", results)
+	}
+}
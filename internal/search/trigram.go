@@ -0,0 +1,61 @@
+package search
+
+import "strings"
+
+// trigramSet is a deduplicated set of lowercased 3-grams.
+type trigramSet map[string]struct{}
+
+// trigramsOf tokenizes s into space-padded, lowercased 3-grams, so
+// matches at the start/end of a title count the same as matches in the
+// middle. Titles in this catalogue are full of non-ASCII text (Japanese
+// romanizations, accented alt titles), so this slides over runes, not
+// bytes.
+func trigramsOf(s string) trigramSet {
+	runes := []rune(" " + strings.ToLower(strings.TrimSpace(s)) + " ")
+
+	set := make(trigramSet)
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = struct{}{}
+	}
+	return set
+}
+
+// jaccard is |a ∩ b| / |a ∪ b|.
+func jaccard(a, b trigramSet) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	intersection := 0
+	for t := range a {
+		if _, ok := b[t]; ok {
+			intersection++
+		}
+	}
+
+	union := len(a) + len(b) - intersection
+	if union == 0 {
+		return 0
+	}
+
+	return float64(intersection) / float64(union)
+}
+
+// hasExactWordMatch reports whether phrase and any of titles share a
+// whole word, case-insensitively. This is the strongest possible
+// signal, so it outranks every fuzzy score.
+func hasExactWordMatch(phrase string, titles []string) bool {
+	phraseWords := strings.Fields(strings.ToLower(phrase))
+
+	for _, title := range titles {
+		titleWords := strings.Fields(strings.ToLower(title))
+		for _, pw := range phraseWords {
+			for _, tw := range titleWords {
+				if pw == tw {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package search
+
+import "testing"
+
+func TestTrigramsOfIsRuneAware(t *testing.T) {
+	set := trigramsOf("Tōkyō Ghoul")
+
+	for tri := range set {
+		if n := len([]rune(tri)); n != 3 {
+			t.Errorf("trigram %q has %d runes, want 3 (byte-sliced mid-codepoint)", tri, n)
+		}
+	}
+
+	if _, ok := set["tōk"]; !ok {
+		t.Errorf("expected trigram %q for %q, got %v", "tōk", "Tōkyō Ghoul", set)
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	a := trigramsOf("one")
+	b := trigramsOf("one")
+	if score := jaccard(a, b); score != 1 {
+		t.Errorf("jaccard of identical sets = %v, want 1", score)
+	}
+
+	if score := jaccard(trigramsOf("one"), trigramsOf("")); score != 0 {
+		t.Errorf("jaccard against an empty set = %v, want 0", score)
+	}
+}
+
+func TestHasExactWordMatch(t *testing.T) {
+	titles := []string{"Attack on Titan", "Shingeki no Kyojin"}
+
+	if !hasExactWordMatch("titan", titles) {
+		t.Error("expected a case-insensitive whole-word match on \"titan\"")
+	}
+
+	if hasExactWordMatch("tita", titles) {
+		t.Error("a partial word should not count as an exact match")
+	}
+}
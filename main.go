@@ -1,50 +1,90 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
 	"log"
-	"math"
 	"net/http"
 	"os"
 	"path"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
-	"github.com/agnivade/levenshtein"
+	"github.com/Roritchi/aniworld-api/internal/cache"
+	"github.com/Roritchi/aniworld-api/internal/downloader"
+	"github.com/Roritchi/aniworld-api/internal/extractors"
+	"github.com/Roritchi/aniworld-api/internal/search"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
 )
 
 const BASE_URL = "https://aniworld.to"
 
-var animesCached []AnimeEntry
+// userAgent is sent on every upstream request; aniworld is more likely
+// to serve a plain Go http.Client's default UA a block page than a
+// browser-shaped one.
+const userAgent = "Mozilla/5.0 (compatible; aniworld-api/1.0; +https://github.com/Roritchi/aniworld-api)"
 
-type AnimeEntry struct {
-	ID                string   `json:"id"`
-	Title             string   `json:"title"`
-	AlternativeTitles []string `json:"alternative_titles"`
-	LinkPath          string   `json:"link_path"`
+// httpClient is shared across every upstream request so connections get
+// reused and nothing can hang forever waiting on aniworld.
+var httpClient = &http.Client{
+	Timeout: 15 * time.Second,
 }
 
-type AnimeInfo struct {
-	Thumbnail string         `json:"thumbnail"`
-	Title     string         `json:"title"`
-	Summary   string         `json:"summary"`
-	Episodes  []EpisodeEntry `json:"episodes"`
+// httpGet issues a GET through httpClient with our User-Agent set.
+func httpGet(url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	return httpClient.Do(req)
 }
 
-type EpisodeEntry struct {
-	LinkPath       string `json:"link_path"`
-	Title          string `json:"title"`
-	SecondaryTitle string `json:"secondary_title"`
-	EpisodeNr      string `json:"episode"`
-	SeasonNr       string `json:"season"`
-}
+// refreshInterval is how often the background refresher re-checks the
+// `/animes` listing and any stale anime entries.
+const refreshInterval = 1 * time.Hour
+
+// downloadsDir is where finished downloads (and the job store backing
+// them) live on disk.
+const downloadsDir = "./downloads"
+
+// defaultDownloadConcurrency is used when DOWNLOAD_CONCURRENCY isn't set.
+const defaultDownloadConcurrency = 2
+
+// defaultSeasonFetchConcurrency is used when SEASON_FETCH_CONCURRENCY
+// isn't set; overridden in main().
+const defaultSeasonFetchConcurrency = 4
+
+var seasonFetchConcurrency = defaultSeasonFetchConcurrency
 
-func parseSeason(doc *goquery.Document) []EpisodeEntry {
+var cacheStore *cache.Store
+var downloadManager *downloader.Manager
+
+// defaultMinSearchScore is the Jaccard trigram score below which a
+// non-exact /search match is dropped, unless the caller overrides it
+// with ?min_score=.
+const defaultMinSearchScore = 0.1
+
+// searchIndex holds the current trigram index over the cached listings.
+// It's rebuilt from scratch (cheap) every time the catalogue changes,
+// via cacheStore.OnListingsUpdated, and read lock-free by /search.
+var searchIndex atomic.Pointer[search.Index]
+
+type AnimeEntry = cache.AnimeEntry
+type AnimeInfo = cache.AnimeInfo
+type EpisodeEntry = cache.EpisodeEntry
+
+// parseSeason parses a single season page into its episodes. animeId is
+// folded into each episode's ID so it stays globally unique even though
+// data-episode-season-id is only unique within the season page.
+func parseSeason(doc *goquery.Document, animeId string) []EpisodeEntry {
 	var episodes []EpisodeEntry
 
 	season := doc.Find("meta[itemprop='seasonNumber']").First().AttrOr("content", "")
@@ -55,12 +95,14 @@ func parseSeason(doc *goquery.Document) []EpisodeEntry {
 		titleSearch := anchor.Find("strong,span")
 		bestTitle := titleSearch.First().Text()
 		secondaryTitle := titleSearch.Last().Text()
+		episodeNr := item.AttrOr("data-episode-season-id", "")
 
 		episode := EpisodeEntry{
+			ID:             fmt.Sprintf("%s-s%s-e%s", animeId, season, episodeNr),
 			LinkPath:       link,
 			Title:          bestTitle,
 			SecondaryTitle: secondaryTitle,
-			EpisodeNr:      item.AttrOr("data-episode-season-id", ""),
+			EpisodeNr:      episodeNr,
 			SeasonNr:       season,
 		}
 
@@ -70,16 +112,61 @@ func parseSeason(doc *goquery.Document) []EpisodeEntry {
 	return episodes
 }
 
-func parseShow(animeId string) AnimeInfo {
-	res, err := http.Get(BASE_URL + "/anime/stream/" + animeId)
+// isStillAiring heuristically decides whether a show is still airing:
+// it is if the last episode on its last season page has no link
+// yet, it hasn't aired (i.e. it's a "coming soon" placeholder).
+func isStillAiring(episodes []EpisodeEntry) bool {
+	if len(episodes) == 0 {
+		return true
+	}
+
+	return strings.TrimSpace(episodes[len(episodes)-1].LinkPath) == ""
+}
+
+// fetchSeasonsConcurrently fetches every season link's episodes in
+// parallel, bounded by seasonFetchConcurrency, and returns them in the
+// same order as links regardless of completion order. It returns the
+// first error encountered, if any.
+func fetchSeasonsConcurrently(links []string, fetchSeason func(link string) ([]EpisodeEntry, error)) ([][]EpisodeEntry, error) {
+	episodesBySeason := make([][]EpisodeEntry, len(links))
+
+	g := new(errgroup.Group)
+	g.SetLimit(seasonFetchConcurrency)
+
+	for i, link := range links {
+		i, link := i, link
+		g.Go(func() error {
+			episodes, err := fetchSeason(link)
+			if err != nil {
+				return err
+			}
+
+			episodesBySeason[i] = episodes
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return episodesBySeason, nil
+}
+
+// parseShow fetches and parses the anime's detail page plus every
+// season page, fanning the season fetches out across a bounded worker
+// pool (seasonFetchConcurrency) instead of fetching them one at a time.
+// The bool return reports whether the show has finished airing, which
+// decides the cache TTL it gets stored under.
+func parseShow(animeId string) (AnimeInfo, bool, error) {
+	res, err := httpGet(BASE_URL + "/anime/stream/" + animeId)
 	if err != nil {
-		fmt.Printf("error making http request: %s\n", err)
-		os.Exit(1)
+		return AnimeInfo{}, false, fmt.Errorf("fetching show page: %w", err)
 	}
 
 	doc, err := goquery.NewDocumentFromReader(res.Body)
 	if err != nil {
-		log.Fatal(err)
+		return AnimeInfo{}, false, fmt.Errorf("parsing show page: %w", err)
 	}
 
 	info := AnimeInfo{
@@ -88,25 +175,34 @@ func parseShow(animeId string) AnimeInfo {
 		Summary:   doc.Find("[itemprop='accessibilitySummary']").First().AttrOr("data-full-description", ""),
 	}
 
-	episodes := parseSeason(doc)
-
+	var seasonLinks []string
 	doc.Find("#stream ul:first-child li a:not(.active)").Each(func(index int, item *goquery.Selection) {
-		link, exists := item.Attr("href")
-		if exists {
-			res, err := http.Get(BASE_URL + link)
-			if err != nil {
-				fmt.Printf("error making http request: %s\n", err)
-				os.Exit(1)
-			}
+		if link, exists := item.Attr("href"); exists {
+			seasonLinks = append(seasonLinks, link)
+		}
+	})
 
-			doc, err := goquery.NewDocumentFromReader(res.Body)
-			if err != nil {
-				log.Fatal(err)
-			}
+	episodesBySeason, err := fetchSeasonsConcurrently(seasonLinks, func(link string) ([]EpisodeEntry, error) {
+		res, err := httpGet(BASE_URL + link)
+		if err != nil {
+			return nil, fmt.Errorf("fetching season page %s: %w", link, err)
+		}
 
-			episodes = append(episodes, parseSeason(doc)...)
+		seasonDoc, err := goquery.NewDocumentFromReader(res.Body)
+		if err != nil {
+			return nil, fmt.Errorf("parsing season page %s: %w", link, err)
 		}
+
+		return parseSeason(seasonDoc, animeId), nil
 	})
+	if err != nil {
+		return AnimeInfo{}, false, err
+	}
+
+	episodes := parseSeason(doc, animeId)
+	for _, s := range episodesBySeason {
+		episodes = append(episodes, s...)
+	}
 
 	sort.Slice(episodes, func(i, j int) bool {
 		if episodes[i].SeasonNr == episodes[j].SeasonNr {
@@ -121,23 +217,237 @@ func parseShow(animeId string) AnimeInfo {
 
 	info.Episodes = episodes
 
-	return info
+	return info, !isStillAiring(episodes), nil
 }
 
-func hasExactWordMatch(phrase string, titles []string) bool {
-	phraseWords := strings.Fields(strings.ToLower(phrase))
+// fetchAnimeCached returns AnimeInfo for animeId from the cache if it's
+// still fresh, otherwise scrapes it and stores the result.
+func fetchAnimeCached(animeId string) (AnimeInfo, error) {
+	if info, ok := cacheStore.GetAnime(animeId); ok && !cacheStore.IsStale(animeId) {
+		return *info, nil
+	}
 
-	for _, title := range titles {
-		titleWords := strings.Fields(strings.ToLower(title))
-		for _, pw := range phraseWords {
-			for _, tw := range titleWords {
-				if pw == tw {
-					return true
-				}
+	info, finished, err := parseShow(animeId)
+	if err != nil {
+		return AnimeInfo{}, err
+	}
+
+	if err := cacheStore.PutAnime(animeId, info, finished); err != nil {
+		log.Println(err)
+	}
+
+	return info, nil
+}
+
+var episodeIDPattern = regexp.MustCompile(`^(.+)-s\d+-e\d+$`)
+
+// episodeAnimeID extracts the animeId embedded in an episode ID of the
+// form "<animeId>-s<season>-e<episode>".
+func episodeAnimeID(episodeId string) (string, bool) {
+	m := episodeIDPattern.FindStringSubmatch(episodeId)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// hosterLink is one hoster option listed on an episode's watch page,
+// before the aniworld redirect has been followed to the real hoster
+// URL.
+type hosterLink struct {
+	Name         string
+	Language     string
+	RedirectPath string
+}
+
+// parseHosters reads every hoster offered on an episode's watch page.
+// aniworld lists them as `.hosterSiteVideo` blocks, each carrying a
+// `.generateInlinePlayer` anchor (whose `data-link-target` is an
+// internal redirect to the actual hoster) and a language flag.
+func parseHosters(doc *goquery.Document) []hosterLink {
+	var hosters []hosterLink
+
+	doc.Find(".hosterSiteVideo").Each(func(index int, item *goquery.Selection) {
+		redirectPath := item.Find("a.generateInlinePlayer").First().AttrOr("data-link-target", "")
+		if redirectPath == "" {
+			return
+		}
+
+		hosters = append(hosters, hosterLink{
+			Name:         strings.TrimSpace(item.Find("h4").First().Text()),
+			Language:     item.Find(".flag").First().AttrOr("data-lang-key", ""),
+			RedirectPath: redirectPath,
+		})
+	})
+
+	return hosters
+}
+
+// rankStreams orders candidates by how well they match the caller's
+// preferred hoster/language, highest score first. Everything else
+// keeps its original (page) order.
+func rankStreams(streams []extractors.Stream, preferHoster, preferLang string) []extractors.Stream {
+	score := func(s extractors.Stream) int {
+		points := 0
+		if preferHoster != "" && strings.EqualFold(s.Hoster, preferHoster) {
+			points += 2
+		}
+		if preferLang != "" && strings.EqualFold(s.Language, preferLang) {
+			points++
+		}
+		return points
+	}
+
+	sort.SliceStable(streams, func(i, j int) bool {
+		return score(streams[i]) > score(streams[j])
+	})
+
+	return streams
+}
+
+// resolveStreams follows an episode's LinkPath to its watch page,
+// resolves every hoster listed there into a direct stream, and ranks
+// the results by the caller's preferred hoster/language.
+func resolveStreams(linkPath, preferHoster, preferLang string) ([]extractors.Stream, error) {
+	res, err := httpGet(BASE_URL + linkPath)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := goquery.NewDocumentFromReader(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var streams []extractors.Stream
+
+	for _, hoster := range parseHosters(doc) {
+		redirectRes, err := httpGet(BASE_URL + hoster.RedirectPath)
+		if err != nil {
+			log.Printf("extractors: following redirect for %s failed: %s\n", hoster.Name, err)
+			continue
+		}
+		redirectRes.Body.Close()
+		hosterURL := redirectRes.Request.URL.String()
+
+		extractor := extractors.For(hosterURL)
+		if extractor == nil {
+			continue
+		}
+
+		stream, err := extractor.Extract(context.Background(), hosterURL)
+		if err != nil {
+			log.Printf("extractors: %s failed for %s: %s\n", extractor.Name(), hosterURL, err)
+			continue
+		}
+
+		if stream.Language == "" {
+			stream.Language = hoster.Language
+		}
+
+		streams = append(streams, stream)
+	}
+
+	return rankStreams(streams, preferHoster, preferLang), nil
+}
+
+// extFromMime maps a resolved stream's mime type to the file extension
+// it should be saved under.
+func extFromMime(mime string) string {
+	switch mime {
+	case "application/x-mpegURL":
+		return ".m3u8"
+	default:
+		return ".mp4"
+	}
+}
+
+// resolveDownload is the downloader package's ResolveFunc: it turns an
+// episode id plus quality/language preference into a concrete stream
+// and destination path, reusing the same cache and extractor lookups
+// /episode/:id uses.
+func resolveDownload(ctx context.Context, episodeID, quality, language string) (downloader.ResolvedDownload, error) {
+	animeId, ok := episodeAnimeID(episodeID)
+	if !ok {
+		return downloader.ResolvedDownload{}, fmt.Errorf("downloader: malformed episode id %q", episodeID)
+	}
+
+	animeInfo, err := fetchAnimeCached(animeId)
+	if err != nil {
+		return downloader.ResolvedDownload{}, err
+	}
+
+	var episode *EpisodeEntry
+	for i := range animeInfo.Episodes {
+		if animeInfo.Episodes[i].ID == episodeID {
+			episode = &animeInfo.Episodes[i]
+			break
+		}
+	}
+	if episode == nil {
+		return downloader.ResolvedDownload{}, fmt.Errorf("downloader: episode %q not found", episodeID)
+	}
+
+	streams, err := resolveStreams(episode.LinkPath, "", language)
+	if err != nil {
+		return downloader.ResolvedDownload{}, err
+	}
+	if len(streams) == 0 {
+		return downloader.ResolvedDownload{}, fmt.Errorf("downloader: no streams resolved for episode %q", episodeID)
+	}
+
+	chosen := streams[0]
+	if quality != "" {
+		for _, s := range streams {
+			if strings.EqualFold(s.Quality, quality) {
+				chosen = s
+				break
 			}
 		}
 	}
-	return false
+
+	season, _ := strconv.Atoi(episode.SeasonNr)
+	episodeNr, _ := strconv.Atoi(episode.EpisodeNr)
+	fileName := fmt.Sprintf("S%02dE%02d%s", season, episodeNr, extFromMime(chosen.MimeType))
+
+	return downloader.ResolvedDownload{
+		StreamURL: chosen.URL,
+		Headers:   chosen.Headers,
+		DestPath:  path.Join(downloadsDir, animeId, fileName),
+	}, nil
+}
+
+func fetchListings() ([]AnimeEntry, error) {
+	res, err := httpGet(BASE_URL + "/animes")
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := goquery.NewDocumentFromReader(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []AnimeEntry
+
+	doc.Find("#seriesContainer li a").Each(func(index int, item *goquery.Selection) {
+		link := item.AttrOr("href", "")
+		id, _ := strings.CutPrefix(link, "/anime/stream/")
+
+		var alternativeTitles []string
+		for _, value := range strings.Split(item.AttrOr("data-alternative-title", ""), ",") {
+			alternativeTitles = append(alternativeTitles, strings.TrimSpace(value))
+		}
+
+		entries = append(entries, AnimeEntry{
+			ID:                id,
+			Title:             item.Text(),
+			AlternativeTitles: alternativeTitles,
+			LinkPath:          link,
+		})
+	})
+
+	return entries, nil
 }
 
 func setupRouter() *gin.Engine {
@@ -163,38 +473,18 @@ func setupRouter() *gin.Engine {
 	})
 
 	r.GET("/animes", func(c *gin.Context) {
-		res, err := http.Get(BASE_URL + "/animes")
-		if err != nil {
-			fmt.Printf("error making http request: %s\n", err)
-			os.Exit(1)
+		if !cacheStore.IsListingsStale() {
+			c.JSON(http.StatusOK, cacheStore.Listings())
+			return
 		}
 
-		doc, err := goquery.NewDocumentFromReader(res.Body)
+		entries, err := fetchListings()
 		if err != nil {
-			log.Fatal(err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
-		var entries []AnimeEntry
-
-		doc.Find("#seriesContainer li a").Each(func(index int, item *goquery.Selection) {
-			link := item.AttrOr("href", "")
-			id, _ := strings.CutPrefix(link, "/anime/stream/")
-
-			var alternativeTitles []string
-			for _, value := range strings.Split(item.AttrOr("data-alternative-title", ""), ",") {
-				alternativeTitles = append(alternativeTitles, strings.TrimSpace(value))
-			}
-
-			entry := AnimeEntry{
-				ID:                id,
-				Title:             item.Text(),
-				AlternativeTitles: alternativeTitles,
-				LinkPath:          link,
-			}
-			entries = append(entries, entry)
-		})
-
-		animesCached = entries
+		cacheStore.SetListings(entries)
 
 		c.JSON(http.StatusOK, entries)
 	})
@@ -202,100 +492,185 @@ func setupRouter() *gin.Engine {
 	r.GET("/play", func(c *gin.Context) {
 		linkPath := c.Query("link_path")
 
-		res, err := http.Get(BASE_URL + linkPath)
+		streams, err := resolveStreams(linkPath, c.Query("hoster"), c.Query("lang"))
 		if err != nil {
-			fmt.Printf("error making http request: %s\n", err)
-			os.Exit(1)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, streams)
+	})
+
+	r.GET("/episode/:id", func(c *gin.Context) {
+		episodeId := c.Params.ByName("id")
+
+		animeId, ok := episodeAnimeID(episodeId)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "malformed episode id"})
+			return
 		}
 
-		doc, err := goquery.NewDocumentFromReader(res.Body)
+		animeInfo, err := fetchAnimeCached(animeId)
 		if err != nil {
-			log.Fatal(err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
-		watchUrl := doc.Find(".generateInlinePlayer a.watchEpisode").First().AttrOr("href", "")
+		var episode *EpisodeEntry
+		for i := range animeInfo.Episodes {
+			if animeInfo.Episodes[i].ID == episodeId {
+				episode = &animeInfo.Episodes[i]
+				break
+			}
+		}
 
-		res, err = http.Get("http://localhost:3000/?url=" + BASE_URL + watchUrl)
+		if episode == nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "episode not found"})
+			return
+		}
+
+		streams, err := resolveStreams(episode.LinkPath, c.Query("hoster"), c.Query("lang"))
 		if err != nil {
-			fmt.Printf("error making http request: %s\n", err)
-			os.Exit(1)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
-		defer res.Body.Close()
 
-		// Optional: check status code
-		if res.StatusCode != http.StatusOK {
-			panic(fmt.Sprintf("unexpected status: %s", res.Status))
+		c.JSON(http.StatusOK, gin.H{
+			"episode": episode,
+			"streams": streams,
+		})
+	})
+
+	r.POST("/downloads", func(c *gin.Context) {
+		var req struct {
+			EpisodeID string `json:"episode_id"`
+			Quality   string `json:"quality"`
+			Language  string `json:"language"`
 		}
 
-		// Read and decode
-		var data map[string]interface{}
-		err = json.NewDecoder(res.Body).Decode(&data)
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.EpisodeID == "" {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "episode_id is required"})
+			return
+		}
+
+		job, err := downloadManager.Enqueue(req.EpisodeID, req.Quality, req.Language)
 		if err != nil {
-			panic(err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
 
-		c.JSON(http.StatusOK, data)
+		c.JSON(http.StatusCreated, job)
 	})
 
-	r.GET("/search", func(c *gin.Context) {
-		var result []AnimeEntry
+	r.GET("/downloads", func(c *gin.Context) {
+		c.JSON(http.StatusOK, downloadManager.List())
+	})
+
+	r.GET("/downloads/:jobId", func(c *gin.Context) {
+		job, ok := downloadManager.Get(c.Params.ByName("jobId"))
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "job not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, job)
+	})
 
-		search := c.Query("phrase")
+	r.DELETE("/downloads/:jobId", func(c *gin.Context) {
+		if err := downloadManager.Cancel(c.Params.ByName("jobId")); err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 
-		result = append(result, animesCached...)
+		c.Status(http.StatusNoContent)
+	})
 
-		sort.Slice(result, func(i, j int) bool {
-			a := result[i]
-			b := result[j]
+	r.GET("/downloads/:jobId/events", func(c *gin.Context) {
+		jobId := c.Params.ByName("jobId")
 
-			titlesA := append([]string{a.Title}, a.AlternativeTitles...)
-			titlesB := append([]string{b.Title}, b.AlternativeTitles...)
+		job, ok := downloadManager.Get(jobId)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "job not found"})
+			return
+		}
 
-			exactA := hasExactWordMatch(search, titlesA)
-			exactB := hasExactWordMatch(search, titlesB)
+		updates, unsubscribe := downloadManager.Subscribe(jobId)
+		defer unsubscribe()
 
-			if exactA && !exactB {
-				return true
-			} else if !exactA && exactB {
-				return false
-			}
+		// Subscribe only delivers future broadcasts, so a job that's
+		// already terminal would otherwise hang here forever: send its
+		// current snapshot first and close right away if there's
+		// nothing left to wait for.
+		c.SSEvent("progress", *job)
+		switch job.Status {
+		case downloader.StatusCompleted, downloader.StatusFailed, downloader.StatusCancelled:
+			return
+		}
 
-			// fallback: Levenshtein
-			distance := func(titles []string) int {
-				best := math.MaxInt
-				for _, title := range titles {
-					if d := levenshtein.ComputeDistance(search, title); d < best {
-						best = d
-					}
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case job, ok := <-updates:
+				if !ok {
+					return false
 				}
-				return best
-			}
 
-			return distance(titlesA) < distance(titlesB)
+				c.SSEvent("progress", job)
+
+				switch job.Status {
+				case downloader.StatusCompleted, downloader.StatusFailed, downloader.StatusCancelled:
+					return false
+				default:
+					return true
+				}
+			case <-c.Request.Context().Done():
+				return false
+			}
 		})
+	})
+
+	r.GET("/search", func(c *gin.Context) {
+		phrase := c.Query("phrase")
 
-		max := 20
-		if len(result) > max {
-			result = result[:max]
+		limit := 20
+		if raw := c.Query("limit"); raw != "" {
+			if v, err := strconv.Atoi(raw); err == nil {
+				limit = v
+			}
 		}
 
-		c.JSON(http.StatusOK, result)
+		minScore := defaultMinSearchScore
+		if raw := c.Query("min_score"); raw != "" {
+			if v, err := strconv.ParseFloat(raw, 64); err == nil {
+				minScore = v
+			}
+		}
+
+		c.JSON(http.StatusOK, searchIndex.Load().Query(phrase, limit, minScore))
 	})
 
 	r.GET("/anime/:id", func(c *gin.Context) {
 		animeId := c.Params.ByName("id")
 
-		animeInfo := parseShow(animeId)
+		animeInfo, err := fetchAnimeCached(animeId)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
 
-		if strings.TrimSpace(animeInfo.Thumbnail) != "" {
-			out, err := os.Create("./cache/" + animeId)
+		thumbPath := "./cache/" + animeId
+		if _, err := os.Stat(thumbPath); err != nil && strings.TrimSpace(animeInfo.Thumbnail) != "" {
+			out, err := os.Create(thumbPath)
 			if err != nil {
 				log.Println(err)
 			}
 			defer out.Close()
 
 			// Get the data
-			resp, err := http.Get(animeInfo.Thumbnail)
+			resp, err := httpGet(animeInfo.Thumbnail)
 			if err != nil {
 				log.Println(err)
 			}
@@ -324,7 +699,11 @@ func setupRouter() *gin.Engine {
 		// Fallback logic if file not found
 		animeId := c.Params.ByName("id")
 
-		animeInfo := parseShow(animeId)
+		animeInfo, err := fetchAnimeCached(animeId)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
 
 		out, err := os.Create("./cache/" + animeId)
 		if err != nil {
@@ -333,7 +712,7 @@ func setupRouter() *gin.Engine {
 		defer out.Close()
 
 		// Get the data
-		resp, err := http.Get(animeInfo.Thumbnail)
+		resp, err := httpGet(animeInfo.Thumbnail)
 		if err != nil {
 			log.Println(err)
 		}
@@ -351,7 +730,58 @@ func setupRouter() *gin.Engine {
 	return r
 }
 
+// registerExtractors wires up one StreamExtractor per known hoster,
+// with the legacy localhost resolver registered last as a catch-all
+// for hosters nobody has implemented yet.
+func registerExtractors() {
+	extractors.Register(extractors.Vidoza{Client: httpClient})
+	extractors.Register(extractors.VOE{Client: httpClient})
+	extractors.Register(extractors.Doodstream{Client: httpClient})
+	extractors.Register(extractors.Streamtape{Client: httpClient})
+
+	fallback := extractors.NewHTTPFallback("http://localhost:3000")
+	fallback.Client = httpClient
+	extractors.Register(fallback)
+}
+
 func main() {
+	registerExtractors()
+
+	if v := os.Getenv("SEASON_FETCH_CONCURRENCY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			seasonFetchConcurrency = n
+		}
+	}
+
+	store, err := cache.NewStore("./cache")
+	if err != nil {
+		log.Fatal(err)
+	}
+	cacheStore = store
+
+	searchIndex.Store(search.NewIndex(cacheStore.Listings()))
+	cacheStore.OnListingsUpdated(func(entries []AnimeEntry) {
+		searchIndex.Store(search.NewIndex(entries))
+	})
+
+	cacheStore.StartRefresher(refreshInterval, fetchListings, parseShow)
+
+	concurrency := defaultDownloadConcurrency
+	if v := os.Getenv("DOWNLOAD_CONCURRENCY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			concurrency = n
+		}
+	}
+
+	downloads, err := downloader.NewManager(downloadsDir, concurrency)
+	if err != nil {
+		log.Fatal(err)
+	}
+	downloadManager = downloads
+	downloadManager.Client = httpClient
+	downloadManager.UserAgent = userAgent
+	downloadManager.Start(resolveDownload)
+
 	r := setupRouter()
 	// Listen and Server in 0.0.0.0:8080
 	r.Run("0.0.0.0:3333")
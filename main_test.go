@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchSeasonsConcurrentlyPreservesOrder(t *testing.T) {
+	links := []string{"/s1", "/s2", "/s3"}
+
+	got, err := fetchSeasonsConcurrently(links, func(link string) ([]EpisodeEntry, error) {
+		// Deliberately resolve season 1 last to prove the result slice
+		// is ordered by input index, not completion order.
+		if link == "/s1" {
+			time.Sleep(50 * time.Millisecond)
+			return []EpisodeEntry{{SeasonNr: "1"}}, nil
+		}
+		return []EpisodeEntry{{SeasonNr: link}}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(got) != len(links) || got[0][0].SeasonNr != "1" || got[1][0].SeasonNr != "/s2" {
+		t.Fatalf("results out of order: %+v", got)
+	}
+}
+
+func TestFetchSeasonsConcurrentlyPropagatesError(t *testing.T) {
+	wantErr := errors.New("season fetch failed")
+
+	_, err := fetchSeasonsConcurrently([]string{"/s1", "/s2"}, func(link string) ([]EpisodeEntry, error) {
+		if link == "/s2" {
+			return nil, wantErr
+		}
+		return nil, nil
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+}
+
+func TestFetchSeasonsConcurrentlyRespectsConcurrencyLimit(t *testing.T) {
+	original := seasonFetchConcurrency
+	seasonFetchConcurrency = 2
+	defer func() { seasonFetchConcurrency = original }()
+
+	var inFlight, maxInFlight int32
+	links := make([]string, 10)
+	for i := range links {
+		links[i] = fmt.Sprintf("/s%d", i)
+	}
+
+	_, err := fetchSeasonsConcurrently(links, func(link string) ([]EpisodeEntry, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			old := atomic.LoadInt32(&maxInFlight)
+			if n <= old || atomic.CompareAndSwapInt32(&maxInFlight, old, n) {
+				break
+			}
+		}
+		atomic.AddInt32(&inFlight, -1)
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if maxInFlight > int32(seasonFetchConcurrency) {
+		t.Errorf("observed %d concurrent fetches, want at most %d", maxInFlight, seasonFetchConcurrency)
+	}
+}
+
+func TestIsStillAiring(t *testing.T) {
+	if !isStillAiring(nil) {
+		t.Error("a show with no parsed episodes should be treated as still airing")
+	}
+
+	airing := []EpisodeEntry{{LinkPath: "/e1"}, {LinkPath: ""}}
+	if !isStillAiring(airing) {
+		t.Error("a last episode with no LinkPath yet means the show hasn't fully aired")
+	}
+
+	finished := []EpisodeEntry{{LinkPath: "/e1"}, {LinkPath: "/e2"}}
+	if isStillAiring(finished) {
+		t.Error("a last episode with a LinkPath means the show has aired")
+	}
+}